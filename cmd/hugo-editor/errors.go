@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// hugoErrorPattern matches Hugo's error format, e.g.:
+//
+//	ERROR 2024/05/01 12:00:00 Error while rendering "content/blog/post.md:12:3": template: ...
+var hugoErrorPattern = regexp.MustCompile(`ERROR.*"([^"]+):(\d+):(\d+)":\s*(.*)`)
+
+// contextLines is how many lines of file context surround a reported error,
+// in each direction.
+const contextLines = 5
+
+// HugoError is a single parsed error from Hugo's stderr, with surrounding
+// source lines attached when the referenced file is one the editor knows
+// about.
+type HugoError struct {
+	File    string   `json:"file"`
+	Line    int      `json:"line"`
+	Column  int      `json:"column"`
+	Message string   `json:"message"`
+	Context []string `json:"context,omitempty"`
+}
+
+// errorMessage is pushed over /ws alongside reloadMessage so the editor can
+// overlay Hugo errors without polling /errors.
+type errorMessage struct {
+	Type  string    `json:"type"`
+	Error HugoError `json:"error"`
+}
+
+// errorBuffer keeps the last N errors the editor has seen from Hugo, for
+// the /errors endpoint.
+type errorBuffer struct {
+	mu     sync.Mutex
+	errors []HugoError
+	max    int
+}
+
+var browserErrors = &errorBuffer{max: 20}
+
+func (b *errorBuffer) add(e HugoError) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.errors = append(b.errors, e)
+	if len(b.errors) > b.max {
+		b.errors = b.errors[len(b.errors)-b.max:]
+	}
+}
+
+func (b *errorBuffer) snapshot() []HugoError {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make([]HugoError, len(b.errors))
+	copy(out, b.errors)
+	return out
+}
+
+// lineScanningWriter buffers partial writes and invokes onLine for each
+// complete line it sees, so stderr from a long-running process (hugo
+// server, a Publisher) can be scanned for errors as it streams by.
+type lineScanningWriter struct {
+	buf    bytes.Buffer
+	onLine func(line string)
+}
+
+func (w *lineScanningWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+
+	for {
+		data := w.buf.Bytes()
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			break
+		}
+		line := strings.TrimRight(string(data[:idx]), "\r")
+		w.buf.Next(idx + 1)
+		w.onLine(line)
+	}
+
+	return len(p), nil
+}
+
+// hugoErrorWriter returns an io.Writer that scans for Hugo's error format
+// and records/broadcasts any it finds. Wrap a process's stderr with
+// io.MultiWriter(original, hugoErrorWriter()) to keep normal logging intact.
+func hugoErrorWriter() io.Writer {
+	return &lineScanningWriter{onLine: captureHugoError}
+}
+
+// captureHugoError parses a single line of output for Hugo's error format
+// and, if it matches, records it (with file context) and pushes it to any
+// connected /ws clients.
+func captureHugoError(line string) {
+	if config.DisableBrowserError {
+		return
+	}
+
+	m := hugoErrorPattern.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+
+	lineNum, _ := strconv.Atoi(m[2])
+	col, _ := strconv.Atoi(m[3])
+
+	hugoErr := HugoError{
+		File:    m[1],
+		Line:    lineNum,
+		Column:  col,
+		Message: strings.TrimSpace(m[4]),
+		Context: fileContext(resolveSitePath(m[1]), lineNum),
+	}
+
+	browserErrors.add(hugoErr)
+	broadcastJSON(errorMessage{Type: "error", Error: hugoErr})
+}
+
+// resolveSitePath resolves a path as Hugo reports it (relative to the site
+// root) against the configured site directory.
+func resolveSitePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(config.HugoSiteDir, path)
+}
+
+// fileContext reads up to contextLines lines before and after line (1-indexed)
+// from path, returning nil if the file can't be read.
+func fileContext(path string, line int) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	start := line - 1 - contextLines
+	if start < 0 {
+		start = 0
+	}
+	end := line + contextLines
+	if end > len(lines) {
+		end = len(lines)
+	}
+	if start >= len(lines) {
+		return nil
+	}
+
+	return lines[start:end]
+}
+
+// handleErrors returns the last errors Hugo has reported, for clients that
+// want to fetch the current state instead of listening on /ws.
+func handleErrors(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(browserErrors.snapshot())
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joeblubaugh/hugo-editor/internal/activitystreams"
+	"github.com/joeblubaugh/hugo-editor/internal/atom"
+)
+
+// generateFeeds walks every published post and writes public/feed.atom plus
+// a per-post ActivityStreams object at public/{section}/{path}.as, matching
+// the shape GoBlog uses for its ActivityPub feed. It's a no-op when
+// -public-url isn't set, since there's no base URL to build absolute links
+// from.
+func generateFeeds() error {
+	if config.PublicBaseURL == "" {
+		return nil
+	}
+
+	publicDir := filepath.Join(config.HugoSiteDir, "public")
+	domain := feedDomain()
+
+	var entries []atom.Entry
+
+	for _, section := range config.Sections {
+		posts, err := findMarkdownFiles(section)
+		if err != nil {
+			return fmt.Errorf("listing posts in section %q: %v", section, err)
+		}
+
+		for _, summary := range posts {
+			post, err := getPost(section, summary.Path)
+			if err != nil {
+				return fmt.Errorf("reading post %s/%s: %v", section, summary.Path, err)
+			}
+
+			fm, body, _, err := ParseFrontMatter([]byte(post.Content))
+			if err != nil {
+				return fmt.Errorf("parsing front matter for %s/%s: %v", section, summary.Path, err)
+			}
+			if fm.Draft {
+				continue
+			}
+
+			published := parsePostDate(fm.Date)
+			slug := strings.TrimSuffix(summary.Path, filepath.Ext(summary.Path))
+			postPath := "/" + filepath.ToSlash(filepath.Join(section, slug)) + "/"
+			postURL := strings.TrimRight(config.PublicBaseURL, "/") + postPath
+			contentHTML := renderHTML(string(body))
+			id := activitystreams.TagURI(domain, published, postPath)
+
+			entries = append(entries, atom.Entry{
+				Title:     fm.Title,
+				ID:        id,
+				Link:      postURL,
+				Published: published,
+				Updated:   published,
+				Content:   contentHTML,
+			})
+
+			obj := activitystreams.New(fm.Title, id, postURL, config.Author, contentHTML, published)
+			asPath := filepath.Join(publicDir, section, slug+".as")
+			if err := writeJSONFile(asPath, obj); err != nil {
+				return fmt.Errorf("writing activitystreams object for %s/%s: %v", section, summary.Path, err)
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Published.After(entries[j].Published)
+	})
+
+	feed := atom.Feed{
+		Title:   "Feed",
+		ID:      strings.TrimRight(config.PublicBaseURL, "/") + "/",
+		Link:    config.PublicBaseURL,
+		Updated: time.Now(),
+		Author:  atom.Person{Name: config.Author},
+		Entries: entries,
+	}
+
+	if err := os.MkdirAll(publicDir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(publicDir, "feed.atom"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return feed.Write(f)
+}
+
+// feedDomain extracts the host from -public-url for use in tag: URIs.
+func feedDomain() string {
+	u, err := url.Parse(config.PublicBaseURL)
+	if err != nil || u.Host == "" {
+		return config.PublicBaseURL
+	}
+	return u.Host
+}
+
+// parsePostDate tries the same date formats handleSave already understands.
+func parsePostDate(raw string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+// renderHTML is a minimal Markdown-to-HTML pass: blank-line-separated
+// paragraphs wrapped in <p>, HTML-escaped. Good enough for a feed preview;
+// it isn't a substitute for Hugo's own rendering.
+func renderHTML(body string) string {
+	var buf strings.Builder
+	for _, para := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		buf.WriteString("<p>")
+		buf.WriteString(html.EscapeString(para))
+		buf.WriteString("</p>\n")
+	}
+	return buf.String()
+}
+
+func writeJSONFile(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter is a typed view of the metadata Hugo stores ahead of a post's
+// body. Fields not recognized here (site-specific or theme-specific keys)
+// are preserved in Extra so ParseFrontMatter doesn't drop them, though their
+// original key order isn't retained.
+type FrontMatter struct {
+	Title      string
+	Date       string
+	Draft      bool
+	Tags       []string
+	Categories []string
+	Slug       string
+	Aliases    []string
+	Extra      map[string]any
+}
+
+// knownFrontMatterKeys are the fields FrontMatter extracts explicitly; every
+// other key found in the document ends up in Extra.
+var knownFrontMatterKeys = map[string]bool{
+	"title": true, "date": true, "draft": true, "tags": true,
+	"categories": true, "slug": true, "aliases": true,
+}
+
+// ParseFrontMatter detects the front matter delimiter (YAML `---`, TOML
+// `+++`, or a leading JSON object) and unmarshals it into a FrontMatter. It
+// returns the remaining body unchanged along with the format name it found
+// ("yaml", "toml", "json", or "" if the document has no front matter at
+// all).
+func ParseFrontMatter(data []byte) (FrontMatter, []byte, string, error) {
+	trimmed := bytes.TrimLeft(data, "\ufeff \t\r\n")
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("---")):
+		raw, body, ok := splitDelimited(trimmed, "---")
+		if !ok {
+			return FrontMatter{}, data, "", nil
+		}
+		var m map[string]any
+		if err := yaml.Unmarshal(raw, &m); err != nil {
+			return FrontMatter{}, data, "", fmt.Errorf("parsing YAML front matter: %v", err)
+		}
+		return frontMatterFromMap(m), body, "yaml", nil
+
+	case bytes.HasPrefix(trimmed, []byte("+++")):
+		raw, body, ok := splitDelimited(trimmed, "+++")
+		if !ok {
+			return FrontMatter{}, data, "", nil
+		}
+		var m map[string]any
+		if err := toml.Unmarshal(raw, &m); err != nil {
+			return FrontMatter{}, data, "", fmt.Errorf("parsing TOML front matter: %v", err)
+		}
+		return frontMatterFromMap(m), body, "toml", nil
+
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		raw, body, ok := splitJSONObject(trimmed)
+		if !ok {
+			return FrontMatter{}, data, "", nil
+		}
+		var m map[string]any
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return FrontMatter{}, data, "", fmt.Errorf("parsing JSON front matter: %v", err)
+		}
+		return frontMatterFromMap(m), body, "json", nil
+	}
+
+	return FrontMatter{}, data, "", nil
+}
+
+// WriteFrontMatter serializes fm back into the given format ("yaml", "toml",
+// or "json"; an unrecognized or empty format defaults to YAML, since that's
+// what handleNew generates for new posts) and reassembles it with body using
+// that format's delimiter. Known fields are written in a fixed order
+// followed by Extra sorted by key, so output is deterministic across calls,
+// but this is not a textual round trip: comments and the original file's
+// key order aren't preserved, since frontMatterFromMap discards both when
+// it unmarshals into a map.
+func WriteFrontMatter(fm FrontMatter, body []byte, format string) ([]byte, error) {
+	pairs := frontMatterPairs(fm)
+
+	switch format {
+	case "toml":
+		encoded, err := encodeTOMLPairs(pairs)
+		if err != nil {
+			return nil, fmt.Errorf("encoding TOML front matter: %v", err)
+		}
+		return joinDelimited("+++", encoded, body), nil
+
+	case "json":
+		encoded, err := encodeJSONPairs(pairs)
+		if err != nil {
+			return nil, fmt.Errorf("encoding JSON front matter: %v", err)
+		}
+		var out bytes.Buffer
+		out.Write(encoded)
+		out.WriteString("\n\n")
+		out.Write(body)
+		return out.Bytes(), nil
+
+	default:
+		encoded, err := encodeYAMLPairs(pairs)
+		if err != nil {
+			return nil, fmt.Errorf("encoding YAML front matter: %v", err)
+		}
+		return joinDelimited("---", encoded, body), nil
+	}
+}
+
+// frontMatterKV is one front matter field, used to fix the output order
+// WriteFrontMatter writes in regardless of format.
+type frontMatterKV struct {
+	Key   string
+	Value any
+}
+
+// frontMatterPairs flattens fm into its output order: known fields first
+// (skipping empty optional ones, matching the old map-based encoder), then
+// Extra sorted by key so unrecognized fields still come out deterministically.
+func frontMatterPairs(fm FrontMatter) []frontMatterKV {
+	pairs := []frontMatterKV{
+		{"title", fm.Title},
+		{"date", fm.Date},
+		{"draft", fm.Draft},
+	}
+	if len(fm.Tags) > 0 {
+		pairs = append(pairs, frontMatterKV{"tags", fm.Tags})
+	}
+	if len(fm.Categories) > 0 {
+		pairs = append(pairs, frontMatterKV{"categories", fm.Categories})
+	}
+	if fm.Slug != "" {
+		pairs = append(pairs, frontMatterKV{"slug", fm.Slug})
+	}
+	if len(fm.Aliases) > 0 {
+		pairs = append(pairs, frontMatterKV{"aliases", fm.Aliases})
+	}
+
+	extraKeys := make([]string, 0, len(fm.Extra))
+	for k := range fm.Extra {
+		extraKeys = append(extraKeys, k)
+	}
+	sort.Strings(extraKeys)
+	for _, k := range extraKeys {
+		pairs = append(pairs, frontMatterKV{k, fm.Extra[k]})
+	}
+
+	return pairs
+}
+
+// encodeYAMLPairs builds a YAML mapping node field by field instead of
+// encoding a map, so the key order in pairs is preserved in the output
+// (yaml.v3 would otherwise sort or randomize map keys).
+func encodeYAMLPairs(pairs []frontMatterKV) ([]byte, error) {
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	for _, kv := range pairs {
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: kv.Key}
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(kv.Value); err != nil {
+			return nil, err
+		}
+		mapping.Content = append(mapping.Content, keyNode, valueNode)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(mapping); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeTOMLPairs writes pairs as "key = value" lines in order. BurntSushi/toml
+// only encodes Go maps (in sorted key order), so there's no encoder to hand
+// ordered pairs to; each value is encoded individually instead.
+func encodeTOMLPairs(pairs []frontMatterKV) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, kv := range pairs {
+		var v bytes.Buffer
+		if err := toml.NewEncoder(&v).Encode(map[string]any{kv.Key: kv.Value}); err != nil {
+			return nil, err
+		}
+		buf.Write(v.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeJSONPairs writes pairs as a JSON object in order, using
+// json.MarshalIndent per value so nested types (tags, aliases) still
+// pretty-print; encoding/json would otherwise only take an unordered map.
+func encodeJSONPairs(pairs []frontMatterKV) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, kv := range pairs {
+		key, err := json.Marshal(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.MarshalIndent(kv.Value, "  ", "  ")
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("  ")
+		buf.Write(key)
+		buf.WriteString(": ")
+		buf.Write(value)
+		if i < len(pairs)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func frontMatterFromMap(m map[string]any) FrontMatter {
+	fm := FrontMatter{Extra: map[string]any{}}
+
+	if v, ok := m["title"].(string); ok {
+		fm.Title = v
+	}
+	if v, ok := m["date"]; ok {
+		if t, ok := v.(time.Time); ok {
+			// BurntSushi/toml decodes TOML dates and datetimes (e.g.
+			// `date = 2024-01-15`) to time.Time rather than string; format
+			// it to the RFC3339 form handleSave and parsePostDate expect.
+			// yaml.v3 keeps unquoted YAML dates as their original string
+			// when decoding into a map, so those take the branch below.
+			fm.Date = t.Format(time.RFC3339)
+		} else {
+			fm.Date = fmt.Sprintf("%v", v)
+		}
+	}
+	if v, ok := m["draft"].(bool); ok {
+		fm.Draft = v
+	}
+	fm.Tags = stringSlice(m["tags"])
+	fm.Categories = stringSlice(m["categories"])
+	if v, ok := m["slug"].(string); ok {
+		fm.Slug = v
+	}
+	fm.Aliases = stringSlice(m["aliases"])
+
+	for k, v := range m {
+		if !knownFrontMatterKeys[k] {
+			fm.Extra[k] = v
+		}
+	}
+
+	return fm
+}
+
+func stringSlice(v any) []string {
+	raw, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// splitDelimited extracts the front matter between the first two lines that
+// are exactly delim, returning the raw front matter and the remaining body.
+func splitDelimited(data []byte, delim string) (raw []byte, body []byte, ok bool) {
+	lines := strings.SplitAfter(string(data), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != delim {
+		return nil, data, false
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == delim {
+			return []byte(strings.Join(lines[1:i], "")), []byte(strings.Join(lines[i+1:], "")), true
+		}
+	}
+
+	return nil, data, false
+}
+
+// splitJSONObject scans a leading `{...}` front matter block by tracking
+// brace depth (ignoring braces inside quoted strings) so JSON front matter
+// doesn't need its own delimiter line.
+func splitJSONObject(data []byte) (raw []byte, body []byte, ok bool) {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch b {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return data[:i+1], bytes.TrimLeft(data[i+1:], "\r\n"), true
+			}
+		}
+	}
+
+	return nil, data, false
+}
+
+// joinDelimited reassembles delim-bounded front matter with body. It writes
+// exactly one newline after the closing delimiter, matching what
+// splitDelimited expects back out of the body it returns - the blank line
+// Hugo posts conventionally show after front matter is part of body itself
+// when there is one, not added here, so WriteFrontMatter -> ParseFrontMatter
+// round-trips byte for byte.
+func joinDelimited(delim string, frontMatter []byte, body []byte) []byte {
+	var out bytes.Buffer
+	out.WriteString(delim)
+	out.WriteString("\n")
+	out.Write(frontMatter)
+	out.WriteString(delim)
+	out.WriteString("\n")
+	out.Write(body)
+	return out.Bytes()
+}
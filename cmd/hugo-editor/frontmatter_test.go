@@ -0,0 +1,211 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseFrontMatterYAML(t *testing.T) {
+	doc := []byte(`---
+title: Hello World
+date: 2024-01-15T10:00:00Z
+draft: true
+tags:
+  - go
+  - hugo
+theme_color: blue
+---
+Body text.
+`)
+
+	fm, body, format, err := ParseFrontMatter(doc)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter: %v", err)
+	}
+	if format != "yaml" {
+		t.Errorf("format = %q, want yaml", format)
+	}
+	if fm.Title != "Hello World" {
+		t.Errorf("Title = %q, want Hello World", fm.Title)
+	}
+	if fm.Date != "2024-01-15T10:00:00Z" {
+		t.Errorf("Date = %q, want 2024-01-15T10:00:00Z", fm.Date)
+	}
+	if !fm.Draft {
+		t.Error("Draft = false, want true")
+	}
+	if len(fm.Tags) != 2 || fm.Tags[0] != "go" || fm.Tags[1] != "hugo" {
+		t.Errorf("Tags = %v, want [go hugo]", fm.Tags)
+	}
+	if fm.Extra["theme_color"] != "blue" {
+		t.Errorf("Extra[theme_color] = %v, want blue", fm.Extra["theme_color"])
+	}
+	if string(body) != "Body text.\n" {
+		t.Errorf("body = %q, want %q", body, "Body text.\n")
+	}
+}
+
+func TestParseFrontMatterUnquotedDate(t *testing.T) {
+	// yaml.v3 resolves an unquoted date like this to time.Time rather than
+	// string; Date must come back formatted as RFC3339, not Go's default
+	// time.Time string representation.
+	doc := []byte(`---
+title: No Quotes
+date: 2024-01-15
+---
+Body.
+`)
+
+	fm, _, _, err := ParseFrontMatter(doc)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter: %v", err)
+	}
+	if fm.Date != "2024-01-15T00:00:00Z" {
+		t.Errorf("Date = %q, want 2024-01-15T00:00:00Z", fm.Date)
+	}
+}
+
+func TestParseFrontMatterTOML(t *testing.T) {
+	doc := []byte(`+++
+title = "TOML Post"
+draft = false
+categories = ["news"]
++++
+Body.
+`)
+
+	fm, _, format, err := ParseFrontMatter(doc)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter: %v", err)
+	}
+	if format != "toml" {
+		t.Errorf("format = %q, want toml", format)
+	}
+	if fm.Title != "TOML Post" {
+		t.Errorf("Title = %q, want TOML Post", fm.Title)
+	}
+	if len(fm.Categories) != 1 || fm.Categories[0] != "news" {
+		t.Errorf("Categories = %v, want [news]", fm.Categories)
+	}
+}
+
+func TestParseFrontMatterJSON(t *testing.T) {
+	doc := []byte(`{
+  "title": "JSON Post",
+  "slug": "json-post"
+}
+Body.
+`)
+
+	fm, _, format, err := ParseFrontMatter(doc)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter: %v", err)
+	}
+	if format != "json" {
+		t.Errorf("format = %q, want json", format)
+	}
+	if fm.Title != "JSON Post" || fm.Slug != "json-post" {
+		t.Errorf("fm = %+v, want Title=JSON Post Slug=json-post", fm)
+	}
+}
+
+func TestParseFrontMatterNone(t *testing.T) {
+	doc := []byte("Just a body, no front matter.\n")
+
+	fm, body, format, err := ParseFrontMatter(doc)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter: %v", err)
+	}
+	if format != "" {
+		t.Errorf("format = %q, want empty", format)
+	}
+	if fm.Title != "" {
+		t.Errorf("Title = %q, want empty", fm.Title)
+	}
+	if string(body) != string(doc) {
+		t.Errorf("body = %q, want unchanged %q", body, doc)
+	}
+}
+
+func TestWriteFrontMatterRoundTrip(t *testing.T) {
+	for _, format := range []string{"yaml", "toml", "json"} {
+		t.Run(format, func(t *testing.T) {
+			fm := FrontMatter{
+				Title:      "Round Trip",
+				Date:       "2024-01-15T10:00:00Z",
+				Draft:      true,
+				Tags:       []string{"go", "hugo"},
+				Categories: []string{"news"},
+				Slug:       "round-trip",
+				Aliases:    []string{"/old-path/"},
+				Extra:      map[string]any{"theme_color": "blue"},
+			}
+			body := []byte("Body text.\n")
+
+			doc, err := WriteFrontMatter(fm, body, format)
+			if err != nil {
+				t.Fatalf("WriteFrontMatter: %v", err)
+			}
+
+			got, gotBody, gotFormat, err := ParseFrontMatter(doc)
+			if err != nil {
+				t.Fatalf("ParseFrontMatter(WriteFrontMatter(...)): %v\ndoc:\n%s", err, doc)
+			}
+			if gotFormat != format {
+				t.Errorf("format = %q, want %q", gotFormat, format)
+			}
+			if string(gotBody) != string(body) {
+				t.Errorf("body = %q, want %q", gotBody, body)
+			}
+			if got.Title != fm.Title || got.Date != fm.Date || got.Draft != fm.Draft || got.Slug != fm.Slug {
+				t.Errorf("round-tripped scalar fields = %+v, want title/date/draft/slug matching %+v", got, fm)
+			}
+			if len(got.Tags) != 2 || got.Tags[0] != "go" || got.Tags[1] != "hugo" {
+				t.Errorf("Tags = %v, want [go hugo]", got.Tags)
+			}
+			if len(got.Categories) != 1 || got.Categories[0] != "news" {
+				t.Errorf("Categories = %v, want [news]", got.Categories)
+			}
+			if len(got.Aliases) != 1 || got.Aliases[0] != "/old-path/" {
+				t.Errorf("Aliases = %v, want [/old-path/]", got.Aliases)
+			}
+			if got.Extra["theme_color"] != "blue" {
+				t.Errorf("Extra[theme_color] = %v, want blue", got.Extra["theme_color"])
+			}
+		})
+	}
+}
+
+func TestWriteFrontMatterFieldOrder(t *testing.T) {
+	fm := FrontMatter{
+		Title: "Ordered",
+		Date:  "2024-01-15T10:00:00Z",
+		Extra: map[string]any{"zz_custom": "z", "aa_custom": "a"},
+	}
+
+	doc, err := WriteFrontMatter(fm, []byte("Body.\n"), "yaml")
+	if err != nil {
+		t.Fatalf("WriteFrontMatter: %v", err)
+	}
+
+	order := []string{"title:", "date:", "draft:", "aa_custom:", "zz_custom:"}
+	last := -1
+	for _, key := range order {
+		idx := indexOf(string(doc), key)
+		if idx < 0 {
+			t.Fatalf("key %q not found in output:\n%s", key, doc)
+		}
+		if idx < last {
+			t.Errorf("key %q appeared out of order in output:\n%s", key, doc)
+		}
+		last = idx
+	}
+}
+
+func indexOf(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if s[i:i+len(substr)] == substr {
+			return i
+		}
+	}
+	return -1
+}
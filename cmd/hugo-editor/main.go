@@ -1,10 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -14,33 +16,89 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	texttemplate "text/template"
 	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gorilla/websocket"
 )
 
 // Config holds the application configuration
 type Config struct {
-	HugoSiteDir    string
-	HugoServerCmd  string
-	PublishCmd     string
-	ServerPort     int
-	AutosaveDelay  time.Duration
-	HugoServerPort int
+	HugoSiteDir         string
+	HugoServerCmd       string
+	PublishCmd          string
+	ServerPort          int
+	AutosaveDelay       time.Duration
+	HugoServerPort      int
+	Sections            []string // content/ subdirectories exposed by the editor
+	DisableBrowserError bool     // suppress the /errors overlay
+	MediaDir            string   // where /media stores uploads, relative to HugoSiteDir
+	MediaMaxSize        int64    // max accepted upload size, in bytes
+	PublicBaseURL       string   // base URL the published site is served from, for feed links
+	Author              string   // attributed author for feed entries
+
+	GitMode        string // "direct" (default), "branch-per-post", or "off"
+	GitAuthorName  string
+	GitAuthorEmail string
+	GitSSHKeyPath  string
+	GitRemoteName  string
+	GitBaseBranch  string // PR base branch in branch-per-post mode
+
+	PRProvider   string // "github", "gitea", or "" to disable PR creation
+	PRRepo       string // "owner/name"
+	GiteaBaseURL string
 }
 
 var (
 	config     Config
 	hugoServer *exec.Cmd
 	mu         sync.Mutex // Mutex for file operations
+
+	// reloadClientsMu guards reloadClients. It's separate from mu so a slow
+	// or stuck /ws client being written to can't block file saves, which
+	// take mu for the whole operation.
+	reloadClientsMu sync.Mutex
+	// reloadClients holds every open /ws connection so savePost and the
+	// content watcher can push reload notifications to them.
+	reloadClients = make(map[*websocket.Conn]bool)
+
+	wsUpgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     func(r *http.Request) bool { return true },
+	}
 )
 
+// wsWriteTimeout bounds how long broadcastJSON will wait on a single
+// client's write before giving up on it, so one wedged connection can't
+// stall the broadcast to everyone else.
+const wsWriteTimeout = 5 * time.Second
+
 func main() {
 	// Parse command line flags
+	var sectionsFlag string
 	flag.StringVar(&config.HugoSiteDir, "site", "", "Path to Hugo site directory")
 	flag.StringVar(&config.HugoServerCmd, "hugo-cmd", "hugo server -D", "Command to run Hugo server")
 	flag.StringVar(&config.PublishCmd, "publish-cmd", "hugo", "Command to build and publish the site")
 	flag.IntVar(&config.ServerPort, "port", 8080, "Port for the editor server")
 	flag.DurationVar(&config.AutosaveDelay, "autosave", 2*time.Second, "Delay for autosaving")
 	flag.IntVar(&config.HugoServerPort, "hugo-port", 1313, "Port for the Hugo server")
+	flag.StringVar(&sectionsFlag, "sections", "", "Comma-separated content/ sections to expose (default: auto-discover every top-level content/ directory)")
+	flag.BoolVar(&config.DisableBrowserError, "disable-browser-error", false, "Disable the in-browser overlay for Hugo template/shortcode errors")
+	flag.StringVar(&config.MediaDir, "media-dir", filepath.Join("static", "uploads"), "Directory (relative to the site) where /media stores uploads")
+	flag.Int64Var(&config.MediaMaxSize, "media-max-size", 20<<20, "Maximum accepted /media upload size, in bytes")
+	flag.StringVar(&config.PublicBaseURL, "public-url", "", "Base URL the published site is served from, used for feed links (feed generation is skipped if unset)")
+	flag.StringVar(&config.Author, "author", "", "Author name attributed in generated feed entries")
+	flag.StringVar(&config.GitMode, "git-mode", "direct", "Git publish mode: direct, branch-per-post, or off")
+	flag.StringVar(&config.GitAuthorName, "git-author-name", "Hugo Editor", "Author name for git commits")
+	flag.StringVar(&config.GitAuthorEmail, "git-author-email", "", "Author email for git commits")
+	flag.StringVar(&config.GitSSHKeyPath, "git-ssh-key", "", "Path to an SSH private key used to push (default: none, rely on an ssh-agent)")
+	flag.StringVar(&config.GitRemoteName, "git-remote", "origin", "Git remote to push to")
+	flag.StringVar(&config.GitBaseBranch, "git-base-branch", "main", "Base branch for branch-per-post pull requests")
+	flag.StringVar(&config.PRProvider, "pr-provider", "", "Pull request provider for branch-per-post mode: github, gitea, or empty to disable")
+	flag.StringVar(&config.PRRepo, "pr-repo", "", "owner/name of the repository to open pull requests against")
+	flag.StringVar(&config.GiteaBaseURL, "gitea-url", "", "Base URL of the Gitea instance, when -pr-provider=gitea")
 	flag.Parse()
 
 	// Validate configuration
@@ -53,15 +111,39 @@ func main() {
 		log.Fatalf("Hugo site directory does not exist: %s", config.HugoSiteDir)
 	}
 
+	if sectionsFlag != "" {
+		for _, s := range strings.Split(sectionsFlag, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				config.Sections = append(config.Sections, s)
+			}
+		}
+	} else {
+		sections, err := discoverSections(config.HugoSiteDir)
+		if err != nil {
+			log.Fatalf("Failed to discover content sections: %v", err)
+		}
+		config.Sections = sections
+	}
+	log.Printf("Serving sections: %s", strings.Join(config.Sections, ", "))
+
 	// Start the Hugo server
 	startHugoServer()
 
+	// Watch every configured section so external edits (git pull, another
+	// editor) also trigger a reload, not just saves made through this app.
+	if err := startContentWatcher(); err != nil {
+		log.Printf("Warning: Failed to start content watcher: %v", err)
+	}
+
 	// Set up HTTP routes
 	http.HandleFunc("/", handleIndex)
 	http.HandleFunc("/edit/", handleEdit)
 	http.HandleFunc("/save", handleSave)
 	http.HandleFunc("/publish", handlePublish)
-	http.HandleFunc("/new", handleNew)
+	http.HandleFunc("/new/", handleNew)
+	http.HandleFunc("/ws", handleWS)
+	http.HandleFunc("/errors", handleErrors)
+	http.HandleFunc("/media", handleMedia)
 
 	// Serve static files
 	fs := http.FileServer(http.Dir("./static"))
@@ -75,6 +157,7 @@ func main() {
 
 // Post represents a Hugo markdown post
 type Post struct {
+	Section string
 	Path    string
 	Content string
 	Title   string
@@ -82,6 +165,53 @@ type Post struct {
 	IsNew   bool
 }
 
+// discoverSections lists the top-level directories under content/, each of
+// which Hugo treats as a section. Callers can skip this with the -sections
+// flag when they only want a subset exposed in the editor.
+func discoverSections(siteDir string) ([]string, error) {
+	contentDir := filepath.Join(siteDir, "content")
+
+	entries, err := os.ReadDir(contentDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading content directory: %v", err)
+	}
+
+	var sections []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			sections = append(sections, entry.Name())
+		}
+	}
+	sort.Strings(sections)
+
+	return sections, nil
+}
+
+// isValidSection reports whether section is one of the sections this editor
+// was configured to serve.
+func isValidSection(section string) bool {
+	for _, s := range config.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// splitSectionPath splits a "{section}/{path...}" URL remainder into its
+// section and path components, validating the section against config.Sections.
+func splitSectionPath(rest string) (section, path string, ok bool) {
+	parts := strings.SplitN(rest, "/", 2)
+	section = parts[0]
+	if !isValidSection(section) {
+		return "", "", false
+	}
+	if len(parts) == 2 {
+		path = parts[1]
+	}
+	return section, path, true
+}
+
 // startHugoServer starts the Hugo server in development mode
 func startHugoServer() {
 	// Split the command into parts
@@ -94,7 +224,7 @@ func startHugoServer() {
 	hugoServer = exec.Command(parts[0], parts[1:]...)
 	hugoServer.Dir = config.HugoSiteDir
 	hugoServer.Stdout = os.Stdout
-	hugoServer.Stderr = os.Stderr
+	hugoServer.Stderr = io.MultiWriter(os.Stderr, hugoErrorWriter())
 
 	// Start the server
 	log.Printf("Starting Hugo server with command: %s", config.HugoServerCmd)
@@ -127,10 +257,10 @@ func stopHugoServer() error {
 	return hugoServer.Wait()
 }
 
-// findMarkdownFiles finds all markdown files in the content directory
-func findMarkdownFiles() ([]Post, error) {
+// findMarkdownFiles finds all markdown files in a content section
+func findMarkdownFiles(section string) ([]Post, error) {
 	var posts []Post
-	contentDir := filepath.Join(config.HugoSiteDir, "content", "blog")
+	contentDir := filepath.Join(config.HugoSiteDir, "content", section)
 
 	err := filepath.Walk(contentDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -160,31 +290,14 @@ func findMarkdownFiles() ([]Post, error) {
 		}
 
 		// Extract title and date from front matter
-		title := ""
-		date := ""
-		lines := strings.Split(string(content), "\n")
-		inFrontMatter := false
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "---" {
-				if !inFrontMatter {
-					inFrontMatter = true
-				} else {
-					break
-				}
-				continue
-			}
-			if inFrontMatter {
-				if strings.HasPrefix(line, "title:") {
-					title = strings.TrimSpace(strings.TrimPrefix(line, "title:"))
-					// Remove quotes if present
-					title = strings.Trim(title, "\"'")
-				} else if strings.HasPrefix(line, "date:") {
-					date = strings.TrimSpace(strings.TrimPrefix(line, "date:"))
-				}
-			}
+		fm, _, _, err := ParseFrontMatter(content)
+		if err != nil {
+			return fmt.Errorf("parsing front matter for %s: %v", relPath, err)
 		}
 
+		title := fm.Title
+		date := fm.Date
+
 		// If no title found, use filename
 		if title == "" {
 			title = strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
@@ -196,9 +309,10 @@ func findMarkdownFiles() ([]Post, error) {
 		}
 
 		posts = append(posts, Post{
-			Path:  relPath,
-			Title: title,
-			Date:  date,
+			Section: section,
+			Path:    relPath,
+			Title:   title,
+			Date:    date,
 		})
 
 		return nil
@@ -215,9 +329,9 @@ func findMarkdownFiles() ([]Post, error) {
 	return posts, nil
 }
 
-// getPost retrieves a specific post by its path
-func getPost(path string) (Post, error) {
-	contentDir := filepath.Join(config.HugoSiteDir, "content", "blog")
+// getPost retrieves a specific post by its section and path
+func getPost(section, path string) (Post, error) {
+	contentDir := filepath.Join(config.HugoSiteDir, "content", section)
 	fullPath := filepath.Join(contentDir, path)
 
 	// Check if file exists
@@ -233,31 +347,14 @@ func getPost(path string) (Post, error) {
 	}
 
 	// Extract title and date from front matter
-	title := ""
-	date := ""
-	lines := strings.Split(string(content), "\n")
-	inFrontMatter := false
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "---" {
-			if !inFrontMatter {
-				inFrontMatter = true
-			} else {
-				break
-			}
-			continue
-		}
-		if inFrontMatter {
-			if strings.HasPrefix(line, "title:") {
-				title = strings.TrimSpace(strings.TrimPrefix(line, "title:"))
-				// Remove quotes if present
-				title = strings.Trim(title, "\"'")
-			} else if strings.HasPrefix(line, "date:") {
-				date = strings.TrimSpace(strings.TrimPrefix(line, "date:"))
-			}
-		}
+	fm, _, _, err := ParseFrontMatter(content)
+	if err != nil {
+		return Post{}, fmt.Errorf("parsing front matter for %s: %v", path, err)
 	}
 
+	title := fm.Title
+	date := fm.Date
+
 	// If no title found, use filename
 	if title == "" {
 		title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(filepath.Base(path)))
@@ -269,6 +366,7 @@ func getPost(path string) (Post, error) {
 	}
 
 	return Post{
+		Section: section,
 		Path:    path,
 		Content: string(content),
 		Title:   title,
@@ -276,12 +374,12 @@ func getPost(path string) (Post, error) {
 	}, nil
 }
 
-// savePost saves the content of a post
-func savePost(path, content string) error {
+// savePost saves the content of a post within a content section
+func savePost(section, path, content string) error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	contentDir := filepath.Join(config.HugoSiteDir, "content", "blog")
+	contentDir := filepath.Join(config.HugoSiteDir, "content", section)
 	fullPath := filepath.Join(contentDir, path)
 
 	// Ensure the directory exists
@@ -294,96 +392,144 @@ func savePost(path, content string) error {
 	return os.WriteFile(fullPath, []byte(content), 0o644)
 }
 
-// gitHasChanges checks if there are any uncommitted changes in the git repository
-func gitHasChanges() (bool, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = config.HugoSiteDir
+// reloadMessage is pushed to every connected /ws client when content changes.
+type reloadMessage struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
 
-	output, err := cmd.Output()
+// handleWS upgrades the connection to a websocket and registers it as a
+// live-reload client. The editor and any proxied post pages served through
+// Hugo connect here; we never read anything meaningful from them, we just
+// keep the connection open so we can push reload events.
+func handleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
 	if err != nil {
-		return false, fmt.Errorf("failed to check git status: %v", err)
+		log.Printf("Error upgrading websocket connection: %v", err)
+		return
 	}
 
-	// If output is empty, there are no changes
-	return len(strings.TrimSpace(string(output))) > 0, nil
-}
-
-// gitCommitChanges creates a git commit with all changes
-func gitCommitChanges() error {
-	// Add all changes
-	addCmd := exec.Command("git", "add", ".")
-	addCmd.Dir = config.HugoSiteDir
-	if err := addCmd.Run(); err != nil {
-		return fmt.Errorf("failed to add changes: %v", err)
-	}
+	reloadClientsMu.Lock()
+	reloadClients[conn] = true
+	reloadClientsMu.Unlock()
 
-	// Create commit with timestamp
-	commitMsg := fmt.Sprintf("Auto-publish: %s", time.Now().Format("2006-01-02 15:04:05"))
-	commitCmd := exec.Command("git", "commit", "-m", commitMsg)
-	commitCmd.Dir = config.HugoSiteDir
+	defer func() {
+		reloadClientsMu.Lock()
+		delete(reloadClients, conn)
+		reloadClientsMu.Unlock()
+		conn.Close()
+	}()
 
-	if err := commitCmd.Run(); err != nil {
-		return fmt.Errorf("failed to commit changes: %v", err)
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
 	}
-
-	log.Printf("Created git commit: %s", commitMsg)
-	return nil
 }
 
-// gitPushChanges pushes the current branch to the remote repository
-func gitPushChanges() error {
-	// Get current branch name
-	branchCmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	branchCmd.Dir = config.HugoSiteDir
+// broadcastReload notifies every connected client that path changed so they
+// can refresh the preview iframe or the post page itself.
+func broadcastReload(path string) {
+	broadcastJSON(reloadMessage{Type: "reload", Path: path})
+}
 
-	branchOutput, err := branchCmd.Output()
+// broadcastJSON marshals v and pushes it to every connected /ws client.
+// reloadMessage and errorMessage both go out this way, distinguished by
+// their "type" field. Each write gets its own deadline and the client set is
+// snapshotted up front, so one slow or stuck connection can't hold
+// reloadClientsMu (or block behind another client's write) and wedge
+// unrelated savePost/handleWS/Hugo-stderr callers.
+func broadcastJSON(v any) {
+	payload, err := json.Marshal(v)
 	if err != nil {
-		return fmt.Errorf("failed to get current branch: %v", err)
+		log.Printf("Error marshaling broadcast message: %v", err)
+		return
 	}
 
-	currentBranch := strings.TrimSpace(string(branchOutput))
-	log.Printf("Pushing branch: %s", currentBranch)
-
-	// Push to remote
-	pushCmd := exec.Command("git", "push", "origin", currentBranch)
-	pushCmd.Dir = config.HugoSiteDir
+	reloadClientsMu.Lock()
+	conns := make([]*websocket.Conn, 0, len(reloadClients))
+	for conn := range reloadClients {
+		conns = append(conns, conn)
+	}
+	reloadClientsMu.Unlock()
 
-	if err := pushCmd.Run(); err != nil {
-		return fmt.Errorf("failed to push changes: %v", err)
+	var dead []*websocket.Conn
+	for _, conn := range conns {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			log.Printf("Error sending broadcast message: %v", err)
+			conn.Close()
+			dead = append(dead, conn)
+		}
 	}
 
-	log.Printf("Successfully pushed changes to remote")
-	return nil
+	if len(dead) > 0 {
+		reloadClientsMu.Lock()
+		for _, conn := range dead {
+			delete(reloadClients, conn)
+		}
+		reloadClientsMu.Unlock()
+	}
 }
 
-// publishSite runs the publish command
-func publishSite() error {
-	// Stop the Hugo server
-	if err := stopHugoServer(); err != nil {
-		log.Printf("Warning: Failed to stop Hugo server: %v", err)
+// startContentWatcher watches every configured section under content/ for
+// changes made outside the editor (a git pull, another editor) and
+// broadcasts the same reload events that savePost does.
+func startContentWatcher() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create content watcher: %v", err)
 	}
 
-	// Split the command into parts
-	parts := strings.Fields(config.PublishCmd)
-	if len(parts) == 0 {
-		return fmt.Errorf("invalid publish command")
+	for _, section := range config.Sections {
+		contentDir := filepath.Join(config.HugoSiteDir, "content", section)
+		if err := filepath.Walk(contentDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return watcher.Add(path)
+			}
+			return nil
+		}); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch section %q: %v", section, err)
+		}
 	}
 
-	// Create the command
-	cmd := exec.Command(parts[0], parts[1:]...)
-	cmd.Dir = config.HugoSiteDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	// Run the command
-	log.Printf("Running publish command: %s", config.PublishCmd)
-	err := cmd.Run()
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				relPath := relativeSectionPath(event.Name)
+				broadcastReload(relPath)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Content watcher error: %v", err)
+			}
+		}
+	}()
 
-	// Restart the Hugo server regardless of publish result
-	log.Println("Restarting Hugo server...")
-	startHugoServer()
+	return nil
+}
 
-	return err
+// relativeSectionPath turns an absolute path under content/{section} into
+// "{section}/{path}" for reload messages.
+func relativeSectionPath(absPath string) string {
+	contentDir := filepath.Join(config.HugoSiteDir, "content")
+	relPath, err := filepath.Rel(contentDir, absPath)
+	if err != nil {
+		return absPath
+	}
+	return relPath
 }
 
 // Create a template function map
@@ -391,18 +537,29 @@ var funcMap = template.FuncMap{
 	"trimSuffix": strings.TrimSuffix,
 }
 
-// handleIndex displays the home page with a list of posts
+// SectionPosts groups the posts found in a single content section, for
+// displaying the index page grouped the way Hugo organizes content.
+type SectionPosts struct {
+	Section string
+	Posts   []Post
+}
+
+// handleIndex displays the home page with a list of posts grouped by section
 func handleIndex(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
 		http.NotFound(w, r)
 		return
 	}
 
-	// Find all markdown files
-	posts, err := findMarkdownFiles()
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Error finding posts: %v", err), http.StatusInternalServerError)
-		return
+	// Find all markdown files, grouped by section
+	var sections []SectionPosts
+	for _, section := range config.Sections {
+		posts, err := findMarkdownFiles(section)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error finding posts in section %q: %v", section, err), http.StatusInternalServerError)
+			return
+		}
+		sections = append(sections, SectionPosts{Section: section, Posts: posts})
 	}
 
 	// Parse template with function map
@@ -414,11 +571,11 @@ func handleIndex(w http.ResponseWriter, r *http.Request) {
 
 	// Render template
 	data := struct {
-		Title string
-		Posts []Post
+		Title    string
+		Sections []SectionPosts
 	}{
-		Title: "Home",
-		Posts: posts,
+		Title:    "Home",
+		Sections: sections,
 	}
 
 	if err := tmpl.Execute(w, data); err != nil {
@@ -428,15 +585,16 @@ func handleIndex(w http.ResponseWriter, r *http.Request) {
 
 // handleEdit displays the editor for a specific post
 func handleEdit(w http.ResponseWriter, r *http.Request) {
-	// Extract path from URL
-	path := strings.TrimPrefix(r.URL.Path, "/edit/")
-	if path == "" {
+	// Extract section and path from the URL: /edit/{section}/{path}
+	rest := strings.TrimPrefix(r.URL.Path, "/edit/")
+	section, path, ok := splitSectionPath(rest)
+	if !ok || path == "" {
 		http.Error(w, "No post specified", http.StatusBadRequest)
 		return
 	}
 
 	// Get the post
-	post, err := getPost(path)
+	post, err := getPost(section, path)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error getting post: %v", err), http.StatusInternalServerError)
 		return
@@ -452,16 +610,18 @@ func handleEdit(w http.ResponseWriter, r *http.Request) {
 	// Render template
 	data := struct {
 		Title      string
+		Section    string
 		Path       string
 		Content    string
 		IsNew      bool
 		PreviewURL string
 	}{
 		Title:      "Edit - " + post.Title,
+		Section:    post.Section,
 		Path:       post.Path,
 		Content:    post.Content,
 		IsNew:      false,
-		PreviewURL: fmt.Sprintf("http://localhost:%d/%s", config.HugoServerPort, strings.TrimSuffix(post.Path, ".md")),
+		PreviewURL: fmt.Sprintf("http://localhost:%d/%s/%s", config.HugoServerPort, post.Section, strings.TrimSuffix(post.Path, ".md")),
 	}
 
 	if err := tmpl.Execute(w, data); err != nil {
@@ -483,12 +643,14 @@ func handleSave(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get form values. We use multi-part in the editor
-	path := r.MultipartForm.Value["path"][0]
-	content := r.MultipartForm.Value["content"][0]
-	fmt.Println(r.Form)
+	// Get form values. We use multi-part in the editor. FormValue returns ""
+	// for a missing field instead of panicking, unlike indexing
+	// r.MultipartForm.Value directly.
+	section := r.FormValue("section")
+	path := r.FormValue("path")
+	content := r.FormValue("content")
 
-	if content == "" {
+	if content == "" || !isValidSection(section) {
 		http.Error(w, "Missing required fields", http.StatusBadRequest)
 		return
 	}
@@ -500,53 +662,36 @@ func handleSave(w http.ResponseWriter, r *http.Request) {
 	year = currentTime.Format("2006")
 	month = currentTime.Format("01")
 
-	lines := strings.Split(content, "\n")
-	inFrontMatter := false
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "---" {
-			if !inFrontMatter {
-				inFrontMatter = true
-			} else {
-				break
-			}
-			continue
-		}
-		if inFrontMatter && strings.HasPrefix(line, "title:") {
-			titleValue := strings.TrimSpace(strings.TrimPrefix(line, "title:"))
-			// Remove quotes if present
-			titleValue = strings.Trim(titleValue, "\"'")
-			if titleValue != "" {
-				title = titleValue
-			}
-		}
+	fm, _, _, err := ParseFrontMatter([]byte(content))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing front matter: %v", err), http.StatusBadRequest)
+		return
+	}
 
-		if inFrontMatter && strings.HasPrefix(line, "date:") {
-			// Try and parse the date from the front matter, get the year and month.
-			dateValue := strings.TrimSpace(strings.TrimPrefix(line, "date:"))
-			// Remove quotes if present
-			dateValue = strings.Trim(dateValue, "\"'")
+	if fm.Title != "" {
+		title = fm.Title
+	}
 
-			// Try different date formats commonly used in Hugo
-			var parsedTime time.Time
-			var err error
+	if fm.Date != "" {
+		// Try different date formats commonly used in Hugo
+		var parsedTime time.Time
+		var err error
 
-			// Try RFC3339 format (2006-01-02T15:04:05Z07:00)
-			parsedTime, err = time.Parse(time.RFC3339, dateValue)
+		// Try RFC3339 format (2006-01-02T15:04:05Z07:00)
+		parsedTime, err = time.Parse(time.RFC3339, fm.Date)
+		if err != nil {
+			// Try simple date format (2006-01-02)
+			parsedTime, err = time.Parse("2006-01-02", fm.Date)
 			if err != nil {
-				// Try simple date format (2006-01-02)
-				parsedTime, err = time.Parse("2006-01-02", dateValue)
-				if err != nil {
-					// Try another common format (2006-01-02 15:04:05)
-					parsedTime, err = time.Parse("2006-01-02 15:04:05", dateValue)
-				}
+				// Try another common format (2006-01-02 15:04:05)
+				parsedTime, err = time.Parse("2006-01-02 15:04:05", fm.Date)
 			}
+		}
 
-			if err == nil {
-				// Get year and month for directory structure (e.g., 2023/05/)
-				year = parsedTime.Format("2006")
-				month = parsedTime.Format("01")
-			}
+		if err == nil {
+			// Get year and month for directory structure (e.g., 2023/05/)
+			year = parsedTime.Format("2006")
+			month = parsedTime.Format("01")
 		}
 	}
 
@@ -561,7 +706,7 @@ func handleSave(w http.ResponseWriter, r *http.Request) {
 	// If path doesn't exist, create it based on the title and date
 	if path == "" {
 		// Ensure slug is unique
-		contentDir := filepath.Join(config.HugoSiteDir, "content", "blog")
+		contentDir := filepath.Join(config.HugoSiteDir, "content", section)
 		fullPath := filepath.Join(contentDir, newFilename)
 
 		// If file exists, add a timestamp to make it unique
@@ -573,10 +718,9 @@ func handleSave(w http.ResponseWriter, r *http.Request) {
 
 		path = newFilename
 	} else {
-		fmt.Println("path", newFilename, "already set")
 		// If the path is out of date, update the path and rename the underlying file
-		oldFullPath := filepath.Join(config.HugoSiteDir, "content", "blog", path)
-		newFullPath := filepath.Join(config.HugoSiteDir, "content", "blog", newFilename)
+		oldFullPath := filepath.Join(config.HugoSiteDir, "content", section, path)
+		newFullPath := filepath.Join(config.HugoSiteDir, "content", section, newFilename)
 
 		// Only rename if the new path is different and doesn't already exist
 		if path != newFilename {
@@ -597,7 +741,7 @@ func handleSave(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save the post
-	err := savePost(path, content)
+	err = savePost(section, path, content)
 	if err != nil {
 		// Return JSON response with error
 		w.Header().Set("Content-Type", "application/json")
@@ -610,15 +754,36 @@ func handleSave(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Notify any connected editor/preview clients that this post changed
+	broadcastReload(filepath.Join(section, path))
+
 	// Return success JSON response
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]any{
 		"success": true,
+		"section": section,
 		"path":    path,
 	})
 }
 
-// handlePublish runs the publish command
+// sseProgress writes each line given to it as a "progress" SSE event, so a
+// Publisher can report output without knowing anything about SSE.
+type sseProgress struct {
+	send func(event, data string)
+}
+
+func (s sseProgress) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if line != "" {
+			s.send("progress", line)
+		}
+	}
+	return len(p), nil
+}
+
+// handlePublish commits and pushes any pending content changes, then runs
+// the Publisher selected by the `target` form field, streaming progress
+// lines back to the browser over SSE so long uploads are visible.
 func handlePublish(w http.ResponseWriter, r *http.Request) {
 	// Only accept POST requests
 	if r.Method != http.MethodPost {
@@ -626,60 +791,83 @@ func handlePublish(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create and push a git commit if there are any changes
-	hasChanges, err := gitHasChanges()
-	if err != nil {
-		log.Printf("Warning: Failed to check git status: %v", err)
-	} else if hasChanges {
-		log.Println("Git changes detected, creating commit...")
-		if err = gitCommitChanges(); err != nil {
-			log.Printf("Warning: Failed to create git commit: %v", err)
-		} else {
-			// Push the git branch if commit was successful
-			log.Println("Pushing changes to remote...")
-			if err = gitPushChanges(); err != nil {
-				log.Printf("Warning: Failed to push changes: %v", err)
-			}
-		}
-	} else {
-		log.Println("No git changes detected, skipping commit")
-	}
+	target := r.FormValue("target")
 
-	if err != nil {
-		// Return JSON response with error
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"success": false,
-			"error":   err.Error(),
-		})
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
 		return
 	}
 
-	// Run the publish command
-	if len(config.PublishCmd) > 0 {
-		err := publishSite()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	sendEvent := func(event, data string) {
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+	}
+
+	// Commit and push pending content changes, unless git integration is
+	// turned off entirely.
+	if config.GitMode != "off" {
+		if err := commitAndPush(r.FormValue("branch"), sendEvent); err != nil {
+			sendEvent("error", err.Error())
+			return
+		}
+	}
+
+	// In branch-per-post mode the edit only exists on the edit/{slug}
+	// branch and commitAndPush leaves the worktree checked back out to the
+	// base branch, so building/deploying here would ship the reverted,
+	// pre-edit tree. Publishing has to wait until the PR merges.
+	if config.GitMode == "branch-per-post" {
+		sendEvent("progress", "Skipping build/deploy: branch-per-post mode publishes once the pull request merges.")
+		payload, err := json.Marshal(PublishResult{})
 		if err != nil {
-			// Return JSON response with error
-			w.Header().Set("Content-Type", "application/json")
-			_ = json.NewEncoder(w).Encode(map[string]any{
-				"success": false,
-				"error":   err.Error(),
-			})
+			sendEvent("error", err.Error())
 			return
 		}
+		sendEvent("done", string(payload))
+		return
 	}
 
-	// Return success JSON response
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"success": true,
-	})
+	publisher, err := publisherForTarget(target)
+	if err != nil {
+		sendEvent("error", err.Error())
+		return
+	}
+
+	result, err := publisher.Publish(sseProgress{send: sendEvent})
+	if err != nil {
+		sendEvent("error", err.Error())
+		return
+	}
+
+	sendEvent("progress", "Generating Atom/ActivityStreams feeds...")
+	if err := generateFeeds(); err != nil {
+		log.Printf("Warning: Failed to generate feeds: %v", err)
+		sendEvent("progress", fmt.Sprintf("Warning: failed to generate feeds: %v", err))
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		sendEvent("error", err.Error())
+		return
+	}
+	sendEvent("done", string(payload))
 }
 
-// handleNew creates a new post
+// handleNew creates a new post in a section: /new/{section}
 func handleNew(w http.ResponseWriter, r *http.Request) {
 	// Handle GET request (show form)
 	if r.Method == http.MethodGet {
+		section := strings.TrimPrefix(r.URL.Path, "/new/")
+		if !isValidSection(section) {
+			http.Error(w, fmt.Sprintf("Unknown section: %s", section), http.StatusBadRequest)
+			return
+		}
+
 		// Parse template with function map
 		tmpl, err := template.New("base.html").Funcs(funcMap).ParseFiles("templates/base.html", "templates/editor.html")
 		if err != nil {
@@ -687,26 +875,19 @@ func handleNew(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Generate a default empty post with front matter
-		now := time.Now().Format("2006-01-02T15:04:05-07:00")
-		defaultContent := fmt.Sprintf(`---
-title: "New Post"
-date: %s
-draft: true
----
-
-Write your content here...
-`, now)
+		defaultContent := newPostArchetype(section)
 
 		// Render template
 		data := struct {
 			Title      string
+			Section    string
 			Path       string
 			Content    string
 			IsNew      bool
 			PreviewURL string
 		}{
 			Title:      "New Post",
+			Section:    section,
 			Path:       "",
 			Content:    defaultContent,
 			IsNew:      true,
@@ -722,3 +903,42 @@ Write your content here...
 	// Method not allowed
 	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 }
+
+// newPostArchetype renders archetypes/{section}.md if the site defines one,
+// matching how Hugo itself scaffolds new content; otherwise it falls back to
+// a minimal default front matter block.
+func newPostArchetype(section string) string {
+	now := time.Now().Format("2006-01-02T15:04:05-07:00")
+
+	archetypePath := filepath.Join(config.HugoSiteDir, "archetypes", section+".md")
+	data, err := os.ReadFile(archetypePath)
+	if err != nil {
+		return fmt.Sprintf(`---
+title: "New Post"
+date: %s
+draft: true
+---
+
+Write your content here...
+`, now)
+	}
+
+	tmpl, err := texttemplate.New("archetype").Parse(string(data))
+	if err != nil {
+		// Not a template we can execute; use the file verbatim.
+		return string(data)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct {
+		Date string
+		Name string
+	}{
+		Date: now,
+		Name: section,
+	}); err != nil {
+		return string(data)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverSections(t *testing.T) {
+	siteDir := t.TempDir()
+	contentDir := filepath.Join(siteDir, "content")
+	for _, section := range []string{"blog", "notes"} {
+		if err := os.MkdirAll(filepath.Join(contentDir, section), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(contentDir, "_index.md"), []byte(""), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sections, err := discoverSections(siteDir)
+	if err != nil {
+		t.Fatalf("discoverSections: %v", err)
+	}
+
+	want := []string{"blog", "notes"}
+	if !reflect.DeepEqual(sections, want) {
+		t.Errorf("discoverSections() = %v, want %v", sections, want)
+	}
+}
+
+func TestIsValidSection(t *testing.T) {
+	orig := config.Sections
+	defer func() { config.Sections = orig }()
+	config.Sections = []string{"blog", "notes"}
+
+	tests := []struct {
+		section string
+		want    bool
+	}{
+		{"blog", true},
+		{"notes", true},
+		{"drafts", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isValidSection(tt.section); got != tt.want {
+			t.Errorf("isValidSection(%q) = %v, want %v", tt.section, got, tt.want)
+		}
+	}
+}
+
+func TestSplitSectionPath(t *testing.T) {
+	orig := config.Sections
+	defer func() { config.Sections = orig }()
+	config.Sections = []string{"blog"}
+
+	tests := []struct {
+		rest        string
+		wantSection string
+		wantPath    string
+		wantOK      bool
+	}{
+		{"blog/2024/post.md", "blog", "2024/post.md", true},
+		{"blog", "blog", "", true},
+		{"notes/post.md", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		section, path, ok := splitSectionPath(tt.rest)
+		if section != tt.wantSection || path != tt.wantPath || ok != tt.wantOK {
+			t.Errorf("splitSectionPath(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.rest, section, path, ok, tt.wantSection, tt.wantPath, tt.wantOK)
+		}
+	}
+}
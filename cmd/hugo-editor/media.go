@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chai2010/webp"
+	"github.com/disintegration/imaging"
+)
+
+// mediaVariantWidths are the responsive image widths generated alongside
+// the original upload, matching Hugo Pipes' usual srcset breakpoints.
+var mediaVariantWidths = []int{480, 960, 1920}
+
+// allowedMediaTypes maps an allow-listed MIME type to the file extension
+// used when it can't be recovered from the original filename.
+var allowedMediaTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// mediaUploadResult is returned from /media so the editor can insert the
+// right Markdown and, if it wants to, offer the responsive variants too.
+type mediaUploadResult struct {
+	URL      string            `json:"url"`
+	Markdown string            `json:"markdown"`
+	Variants map[string]string `json:"variants,omitempty"`
+}
+
+// handleMedia accepts a multipart image upload, stores it under
+// config.MediaDir (static/uploads/YYYY/MM by default, or a configured
+// assets/ dir for Hugo Pipes), and generates resized webp variants. It
+// returns the Markdown snippet to insert into the post being edited.
+func handleMedia(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseMultipartForm(config.MediaMaxSize); err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing form: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error reading upload: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, config.MediaMaxSize+1))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error reading upload: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if int64(len(data)) > config.MediaMaxSize {
+		http.Error(w, fmt.Sprintf("Upload exceeds the %d byte limit", config.MediaMaxSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, allowed := allowedMediaTypes[contentType]
+	if !allowed {
+		http.Error(w, fmt.Sprintf("Unsupported media type: %s", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+	if headerExt := filepath.Ext(header.Filename); headerExt != "" {
+		ext = headerExt
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:12]
+
+	now := time.Now()
+	relDir := filepath.Join(config.MediaDir, now.Format("2006"), now.Format("01"))
+	dir := filepath.Join(config.HugoSiteDir, relDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		http.Error(w, fmt.Sprintf("Error creating upload directory: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	filename := hash + ext
+	if err := os.WriteFile(filepath.Join(dir, filename), data, 0o644); err != nil {
+		http.Error(w, fmt.Sprintf("Error saving upload: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	variants, err := generateImageVariants(data, dir, hash)
+	if err != nil {
+		log.Printf("Warning: failed to generate responsive variants for %s: %v", filename, err)
+	}
+
+	url := "/" + filepath.ToSlash(strings.TrimPrefix(filepath.Join(relDir, filename), "static"+string(filepath.Separator)))
+
+	result := mediaUploadResult{
+		URL:      url,
+		Markdown: fmt.Sprintf("![](%s)", url),
+		Variants: variants,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// generateImageVariants decodes the uploaded image and writes resized webp
+// copies at each of mediaVariantWidths that's narrower than the original,
+// keyed by "{width}w". Decoding and re-encoding also strips EXIF, since Go's
+// image decoders don't carry EXIF through to the decoded image.Image.
+func generateImageVariants(data []byte, dir, baseName string) (map[string]string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %v", err)
+	}
+
+	variants := map[string]string{}
+	originalWidth := img.Bounds().Dx()
+
+	for _, width := range mediaVariantWidths {
+		if width >= originalWidth {
+			continue
+		}
+
+		resized := imaging.Resize(img, width, 0, imaging.Lanczos)
+
+		var buf bytes.Buffer
+		if err := webp.Encode(&buf, resized, &webp.Options{Quality: 85}); err != nil {
+			return variants, fmt.Errorf("encoding %dw webp variant: %v", width, err)
+		}
+
+		variantName := fmt.Sprintf("%s-%dw.webp", baseName, width)
+		if err := os.WriteFile(filepath.Join(dir, variantName), buf.Bytes(), 0o644); err != nil {
+			return variants, fmt.Errorf("writing %dw webp variant: %v", width, err)
+		}
+
+		variants[fmt.Sprintf("%dw", width)] = variantName
+	}
+
+	return variants, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x % 256), G: uint8(y % 256), B: 0, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encoding test PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateImageVariantsResizesNarrowerWidths(t *testing.T) {
+	dir := t.TempDir()
+	data := encodeTestPNG(t, 1000, 500)
+
+	variants, err := generateImageVariants(data, dir, "testimg")
+	if err != nil {
+		t.Fatalf("generateImageVariants: %v", err)
+	}
+
+	// Of mediaVariantWidths (480, 960, 1920), only widths narrower than the
+	// 1000px original should be generated.
+	if _, ok := variants["480w"]; !ok {
+		t.Error("expected 480w variant")
+	}
+	if _, ok := variants["960w"]; !ok {
+		t.Error("expected 960w variant")
+	}
+	if _, ok := variants["1920w"]; ok {
+		t.Error("did not expect 1920w variant for a 1000px-wide original")
+	}
+
+	for width, name := range variants {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("variant %s: file not written at %s: %v", width, path, err)
+		}
+		img, _, err := image.Decode(mustOpen(t, path))
+		if err != nil {
+			t.Fatalf("decoding variant %s: %v", width, err)
+		}
+		gotWidth := img.Bounds().Dx()
+		wantWidth := 480
+		if width == "960w" {
+			wantWidth = 960
+		}
+		if gotWidth != wantWidth {
+			t.Errorf("variant %s width = %d, want %d", width, gotWidth, wantWidth)
+		}
+	}
+}
+
+func mustOpen(t *testing.T, path string) *os.File {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening %s: %v", path, err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestGenerateImageVariantsNoVariantsForSmallOriginal(t *testing.T) {
+	dir := t.TempDir()
+	data := encodeTestPNG(t, 100, 100)
+
+	variants, err := generateImageVariants(data, dir, "tiny")
+	if err != nil {
+		t.Fatalf("generateImageVariants: %v", err)
+	}
+	if len(variants) != 0 {
+		t.Errorf("variants = %v, want none for a 100px-wide original", variants)
+	}
+}
+
+func newMediaUploadRequest(t *testing.T, data []byte, filename string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/media", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestHandleMediaHashesAndStoresUpload(t *testing.T) {
+	origSiteDir, origMediaDir, origMaxSize := config.HugoSiteDir, config.MediaDir, config.MediaMaxSize
+	defer func() {
+		config.HugoSiteDir, config.MediaDir, config.MediaMaxSize = origSiteDir, origMediaDir, origMaxSize
+	}()
+
+	config.HugoSiteDir = t.TempDir()
+	config.MediaDir = "static/uploads"
+	config.MediaMaxSize = 10 * 1024 * 1024
+
+	data := encodeTestPNG(t, 50, 50)
+	sum := sha256.Sum256(data)
+	wantHash := hex.EncodeToString(sum[:])[:12]
+
+	req := newMediaUploadRequest(t, data, "photo.png")
+	rec := httptest.NewRecorder()
+	handleMedia(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var result mediaUploadResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if want := wantHash + ".png"; filepath.Base(result.URL) != want {
+		t.Errorf("URL basename = %q, want %q", filepath.Base(result.URL), want)
+	}
+}
+
+func TestHandleMediaRejectsOversizedUpload(t *testing.T) {
+	origSiteDir, origMediaDir, origMaxSize := config.HugoSiteDir, config.MediaDir, config.MediaMaxSize
+	defer func() {
+		config.HugoSiteDir, config.MediaDir, config.MediaMaxSize = origSiteDir, origMediaDir, origMaxSize
+	}()
+
+	config.HugoSiteDir = t.TempDir()
+	config.MediaDir = "static/uploads"
+	config.MediaMaxSize = 16
+
+	data := encodeTestPNG(t, 50, 50)
+
+	req := newMediaUploadRequest(t, data, "photo.png")
+	rec := httptest.NewRecorder()
+	handleMedia(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// PublishResult summarizes what a Publisher actually did, so the editor UI
+// can show something more useful than a bare success/failure flag.
+type PublishResult struct {
+	Uploaded int `json:"uploaded"`
+	Skipped  int `json:"skipped"`
+	Deleted  int `json:"deleted"`
+}
+
+// Publisher builds and/or ships the site to a destination. Progress lines
+// are written to w as they happen so handlePublish can stream them to the
+// browser over SSE.
+type Publisher interface {
+	Publish(w io.Writer) (PublishResult, error)
+}
+
+// PublishConfig is loaded from hugo-editor.toml in the site directory. Each
+// entry under [targets] configures one Publisher; the target name is chosen
+// by the `target` form field on /publish.
+type PublishConfig struct {
+	Targets map[string]PublishTargetConfig `toml:"targets"`
+}
+
+// PublishTargetConfig describes a single named publish target. Which fields
+// matter depends on Type.
+type PublishTargetConfig struct {
+	Type string `toml:"type"` // "hugo" (default), "deploy", or "rsync"
+
+	// type = "hugo"
+	Cmd string `toml:"cmd,omitempty"`
+
+	// type = "deploy" (hugo deploy to S3/GCS/Azure, see Hugo's own deploy command)
+	URL          string            `toml:"url,omitempty"`
+	Region       string            `toml:"region,omitempty"`
+	CacheControl map[string]string `toml:"cache_control,omitempty"`
+	ContentType  map[string]string `toml:"content_type,omitempty"`
+
+	// type = "rsync"
+	Host       string `toml:"host,omitempty"`
+	RemotePath string `toml:"remote_path,omitempty"`
+	SSHKey     string `toml:"ssh_key,omitempty"`
+
+	DryRun bool `toml:"dry_run,omitempty"`
+}
+
+// loadPublishConfig reads hugo-editor.toml from the site directory. It is
+// not an error for the file to be missing: sites that don't need anything
+// beyond the classic hugo-build-and-push flow don't need one.
+func loadPublishConfig(siteDir string) (PublishConfig, error) {
+	var cfg PublishConfig
+
+	path := filepath.Join(siteDir, "hugo-editor.toml")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing hugo-editor.toml: %v", err)
+	}
+
+	return cfg, nil
+}
+
+// publisherForTarget resolves the `target` form field from /publish to a
+// Publisher. An empty target, or "hugo" when hugo-editor.toml doesn't
+// configure it, falls back to the classic hugo-build publisher so existing
+// sites keep working without a config file; any other target that isn't
+// configured there is an error.
+func publisherForTarget(target string) (Publisher, error) {
+	cfg, err := loadPublishConfig(config.HugoSiteDir)
+	if err != nil {
+		return nil, err
+	}
+
+	targetCfg, ok := cfg.Targets[target]
+	if !ok {
+		if target != "" && target != "hugo" {
+			return nil, fmt.Errorf("unknown publish target: %s", target)
+		}
+		return &HugoBuildPublisher{Cmd: config.PublishCmd, SiteDir: config.HugoSiteDir}, nil
+	}
+
+	switch targetCfg.Type {
+	case "deploy":
+		return &DeployPublisher{Name: target, Config: targetCfg, SiteDir: config.HugoSiteDir}, nil
+	case "rsync":
+		return &RsyncPublisher{Config: targetCfg, SiteDir: config.HugoSiteDir}, nil
+	default:
+		cmd := targetCfg.Cmd
+		if cmd == "" {
+			cmd = config.PublishCmd
+		}
+		return &HugoBuildPublisher{Cmd: cmd, SiteDir: config.HugoSiteDir}, nil
+	}
+}
+
+// HugoBuildPublisher runs `hugo` (or whatever -publish-cmd is set to) in the
+// site directory. This is the original behavior: build the site, relying on
+// the caller (handlePublish) to have already committed and pushed the
+// content changes with git.
+type HugoBuildPublisher struct {
+	Cmd     string
+	SiteDir string
+}
+
+func (p *HugoBuildPublisher) Publish(w io.Writer) (PublishResult, error) {
+	if err := stopHugoServer(); err != nil {
+		log.Printf("Warning: Failed to stop Hugo server: %v", err)
+	}
+	defer startHugoServer()
+
+	parts := strings.Fields(p.Cmd)
+	if len(parts) == 0 {
+		return PublishResult{}, fmt.Errorf("invalid publish command")
+	}
+
+	fmt.Fprintf(w, "Running publish command: %s\n", p.Cmd)
+
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Dir = p.SiteDir
+	cmd.Stdout = w
+	cmd.Stderr = io.MultiWriter(w, hugoErrorWriter())
+
+	if err := cmd.Run(); err != nil {
+		return PublishResult{}, err
+	}
+
+	return PublishResult{}, nil
+}
+
+// DeployPublisher uploads the built site to S3/GCS/Azure via `hugo deploy`.
+// hugo deploy has no --region or --matchers flags: a target's URL and its
+// cacheControl/contentType matchers are read from the [deployment] table in
+// the site's own Hugo config, keyed by --target. So rather than guessing at
+// CLI flags, Publish writes that table to a generated config file and merges
+// it in alongside the site's existing config via Hugo's comma-separated
+// --config list.
+type DeployPublisher struct {
+	Name    string // target name, also used as [[deployment.targets]].name
+	Config  PublishTargetConfig
+	SiteDir string
+}
+
+var hugoDeploySummary = regexp.MustCompile(`(\d+) uploaded,\s*(\d+) skipped,\s*(\d+) deleted`)
+
+// hugoDeployConfig mirrors the subset of Hugo's [deployment] config schema
+// DeployPublisher needs to generate: https://gohugo.io/hosting-and-deployment/hugo-deploy/
+type hugoDeployConfig struct {
+	Deployment hugoDeployment `toml:"deployment"`
+}
+
+type hugoDeployment struct {
+	Targets  []hugoDeployTarget  `toml:"targets"`
+	Matchers []hugoDeployMatcher `toml:"matchers"`
+}
+
+type hugoDeployTarget struct {
+	Name string `toml:"name"`
+	URL  string `toml:"URL"`
+}
+
+type hugoDeployMatcher struct {
+	Pattern      string `toml:"pattern"`
+	CacheControl string `toml:"cacheControl,omitempty"`
+	ContentType  string `toml:"contentType,omitempty"`
+}
+
+func (p *DeployPublisher) Publish(w io.Writer) (PublishResult, error) {
+	url := p.Config.URL
+	if p.Config.Region != "" {
+		sep := "?"
+		if strings.Contains(url, "?") {
+			sep = "&"
+		}
+		url += sep + "region=" + p.Config.Region
+	}
+
+	deployCfg := hugoDeployConfig{Deployment: hugoDeployment{
+		Targets: []hugoDeployTarget{{Name: p.Name, URL: url}},
+	}}
+	for glob, cacheControl := range p.Config.CacheControl {
+		deployCfg.Deployment.Matchers = append(deployCfg.Deployment.Matchers, hugoDeployMatcher{Pattern: glob, CacheControl: cacheControl})
+	}
+	for glob, contentType := range p.Config.ContentType {
+		deployCfg.Deployment.Matchers = append(deployCfg.Deployment.Matchers, hugoDeployMatcher{Pattern: glob, ContentType: contentType})
+	}
+
+	configPath, cleanup, err := writeDeployConfig(p.SiteDir, deployCfg)
+	if err != nil {
+		return PublishResult{}, err
+	}
+	defer cleanup()
+
+	configFlag := configPath
+	if base := siteConfigFile(p.SiteDir); base != "" {
+		configFlag = base + "," + configPath
+	}
+
+	args := []string{"deploy", "--target", p.Name, "--config", configFlag}
+	if p.Config.DryRun {
+		args = append(args, "--dryRun")
+	}
+
+	fmt.Fprintf(w, "Deploying to %s via hugo deploy...\n", url)
+
+	cmd := exec.Command("hugo", args...)
+	cmd.Dir = p.SiteDir
+
+	var result PublishResult
+	if err := runAndStream(cmd, w, func(line string) {
+		if m := hugoDeploySummary.FindStringSubmatch(line); m != nil {
+			result = PublishResult{Uploaded: atoiOrZero(m[1]), Skipped: atoiOrZero(m[2]), Deleted: atoiOrZero(m[3])}
+		}
+	}); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// siteConfigFileCandidates are the config file names Hugo looks for by
+// default, in the order it checks them.
+var siteConfigFileCandidates = []string{"hugo.toml", "hugo.yaml", "hugo.yml", "hugo.json", "config.toml", "config.yaml", "config.yml", "config.json"}
+
+// siteConfigFile finds the site's own Hugo config file, if any, so it can be
+// merged with the generated deploy config rather than replaced by it.
+func siteConfigFile(siteDir string) string {
+	for _, name := range siteConfigFileCandidates {
+		if _, err := os.Stat(filepath.Join(siteDir, name)); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// writeDeployConfig writes cfg to a temp TOML file in siteDir (so it's a
+// relative path Hugo's --config can merge with the site's own config file)
+// and returns a cleanup func that removes it.
+func writeDeployConfig(siteDir string, cfg hugoDeployConfig) (path string, cleanup func(), err error) {
+	f, err := os.CreateTemp(siteDir, "hugo-editor-deploy-*.toml")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating deploy config: %v", err)
+	}
+	defer f.Close()
+
+	if err := toml.NewEncoder(f).Encode(cfg); err != nil {
+		os.Remove(f.Name())
+		return "", nil, fmt.Errorf("encoding deploy config: %v", err)
+	}
+
+	name := filepath.Base(f.Name())
+	return name, func() { os.Remove(f.Name()) }, nil
+}
+
+// RsyncPublisher ships the built site to a remote host over SSH with rsync.
+type RsyncPublisher struct {
+	Config  PublishTargetConfig
+	SiteDir string
+}
+
+func (p *RsyncPublisher) Publish(w io.Writer) (PublishResult, error) {
+	args := []string{"-avz", "--delete", "--itemize-changes"}
+	if p.Config.DryRun {
+		args = append(args, "--dry-run")
+	}
+	if p.Config.SSHKey != "" {
+		args = append(args, "-e", fmt.Sprintf("ssh -i %s", p.Config.SSHKey))
+	}
+
+	src := filepath.Join(p.SiteDir, "public") + "/"
+	dst := fmt.Sprintf("%s:%s", p.Config.Host, p.Config.RemotePath)
+	args = append(args, src, dst)
+
+	fmt.Fprintf(w, "Running rsync to %s...\n", dst)
+
+	cmd := exec.Command("rsync", args...)
+
+	var result PublishResult
+	if err := runAndStream(cmd, w, func(line string) {
+		switch {
+		case strings.HasPrefix(line, ">f"):
+			result.Uploaded++
+		case strings.HasPrefix(line, "*deleting"):
+			result.Deleted++
+		}
+	}); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// runAndStream runs cmd, copying its combined output to w line by line and
+// calling onLine for each one, so callers can both show progress and scrape
+// summary counts out of tool-specific output formats.
+func runAndStream(cmd *exec.Cmd, w io.Writer, onLine func(line string)) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Fprintln(w, line)
+		captureHugoError(line)
+		onLine(line)
+	}
+
+	return cmd.Wait()
+}
+
+func atoiOrZero(s string) int {
+	n := 0
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Tokens are read from the environment rather than flags so they don't show
+// up in `ps` output or shell history.
+func githubToken() string { return os.Getenv("GITHUB_TOKEN") }
+func giteaToken() string  { return os.Getenv("GITEA_TOKEN") }
+
+// PRProvider opens a pull/merge request for a pushed branch, so
+// branch-per-post git mode can hand the change to a reviewer instead of
+// pushing straight to the default branch.
+type PRProvider interface {
+	CreatePullRequest(branch, base, title, body string) (url string, err error)
+}
+
+// prProviderForConfig resolves the -pr-provider flag to a PRProvider. An
+// empty or "none" value disables PR creation: branch-per-post mode will
+// still push the branch, it just won't open anything against it.
+func prProviderForConfig() PRProvider {
+	switch config.PRProvider {
+	case "github":
+		return &GitHubPRProvider{Repo: config.PRRepo, Token: githubToken()}
+	case "gitea":
+		return &GiteaPRProvider{BaseURL: config.GiteaBaseURL, Repo: config.PRRepo, Token: giteaToken()}
+	default:
+		return nil
+	}
+}
+
+// GitHubPRProvider opens a pull request via the GitHub REST API.
+type GitHubPRProvider struct {
+	Repo  string // "owner/name"
+	Token string
+}
+
+func (p *GitHubPRProvider) CreatePullRequest(branch, base, title, body string) (string, error) {
+	payload, err := json.Marshal(map[string]string{
+		"title": title,
+		"head":  branch,
+		"base":  base,
+		"body":  body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("https://api.github.com/repos/%s/pulls", p.Repo), bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("creating GitHub pull request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("GitHub API returned %s creating pull request", resp.Status)
+	}
+
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+
+	return created.HTMLURL, nil
+}
+
+// GiteaPRProvider opens a pull request via the Gitea REST API.
+type GiteaPRProvider struct {
+	BaseURL string
+	Repo    string // "owner/name"
+	Token   string
+}
+
+func (p *GiteaPRProvider) CreatePullRequest(branch, base, title, body string) (string, error) {
+	payload, err := json.Marshal(map[string]string{
+		"title": title,
+		"head":  branch,
+		"base":  base,
+		"body":  body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/v1/repos/%s/pulls", p.BaseURL, p.Repo), bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "token "+p.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("creating Gitea pull request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("Gitea API returned %s creating pull request", resp.Status)
+	}
+
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+
+	return created.HTMLURL, nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// VCS is the version-control operations handlePublish needs. goGitVCS is its
+// only implementation; git mode "off" (config.GitMode == "off") is handled by
+// handlePublish skipping commitAndPush entirely.
+type VCS interface {
+	HasChanges() (bool, error)
+	CommitAll(message string) error
+	CurrentBranch() (string, error)
+	CreateBranch(name string) error
+	Checkout(name string) error
+	Push(branch string) error
+}
+
+// goGitVCS backs VCS with go-git instead of shelling out to the git binary,
+// so commits are attributed with config's author identity regardless of
+// what's in the user's global gitconfig.
+type goGitVCS struct {
+	repo   *git.Repository
+	auth   transport.AuthMethod
+	author object.Signature
+	remote string
+}
+
+func newGoGitVCS(siteDir string) (*goGitVCS, error) {
+	repo, err := git.PlainOpen(siteDir)
+	if err != nil {
+		return nil, fmt.Errorf("opening git repository: %v", err)
+	}
+
+	var auth transport.AuthMethod
+	if config.GitSSHKeyPath != "" {
+		keys, err := ssh.NewPublicKeysFromFile("git", config.GitSSHKeyPath, "")
+		if err != nil {
+			return nil, fmt.Errorf("loading SSH key %q: %v", config.GitSSHKeyPath, err)
+		}
+		auth = keys
+	}
+
+	remote := config.GitRemoteName
+	if remote == "" {
+		remote = "origin"
+	}
+
+	return &goGitVCS{
+		repo: repo,
+		auth: auth,
+		author: object.Signature{
+			Name:  config.GitAuthorName,
+			Email: config.GitAuthorEmail,
+			When:  time.Now(),
+		},
+		remote: remote,
+	}, nil
+}
+
+func (v *goGitVCS) HasChanges() (bool, error) {
+	wt, err := v.repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return false, fmt.Errorf("checking git status: %v", err)
+	}
+
+	return !status.IsClean(), nil
+}
+
+func (v *goGitVCS) CommitAll(message string) error {
+	wt, err := v.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	if _, err := wt.Add("."); err != nil {
+		return fmt.Errorf("staging changes: %v", err)
+	}
+
+	sig := v.author
+	sig.When = time.Now()
+
+	if _, err := wt.Commit(message, &git.CommitOptions{Author: &sig}); err != nil {
+		return fmt.Errorf("committing changes: %v", err)
+	}
+
+	return nil
+}
+
+func (v *goGitVCS) CurrentBranch() (string, error) {
+	head, err := v.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("resolving current branch: %v", err)
+	}
+	return head.Name().Short(), nil
+}
+
+// CreateBranch creates name pointing at the current HEAD if it doesn't
+// already exist.
+func (v *goGitVCS) CreateBranch(name string) error {
+	head, err := v.repo.Head()
+	if err != nil {
+		return fmt.Errorf("resolving HEAD: %v", err)
+	}
+
+	refName := plumbing.NewBranchReferenceName(name)
+	if _, err := v.repo.Reference(refName, false); err == nil {
+		return nil
+	}
+
+	ref := plumbing.NewHashReference(refName, head.Hash())
+	if err := v.repo.Storer.SetReference(ref); err != nil {
+		return fmt.Errorf("creating branch %q: %v", name, err)
+	}
+
+	return nil
+}
+
+func (v *goGitVCS) Checkout(name string) error {
+	wt, err := v.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	opts := &git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(name)}
+	// Switching to a branch at HEAD's own commit (a freshly created
+	// edit/{slug} branch) carries uncommitted changes along, as git checkout
+	// does; a plain go-git checkout refuses a worktree with modified files.
+	if head, err := v.repo.Head(); err == nil {
+		if ref, err := v.repo.Reference(opts.Branch, true); err == nil && ref.Hash() == head.Hash() {
+			opts.Keep = true
+		}
+	}
+
+	if err := wt.Checkout(opts); err != nil {
+		return fmt.Errorf("checking out %q: %v", name, err)
+	}
+
+	return nil
+}
+
+func (v *goGitVCS) Push(branch string) error {
+	refSpec := gitconfig.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch))
+
+	err := v.repo.Push(&git.PushOptions{
+		RemoteName: v.remote,
+		RefSpecs:   []gitconfig.RefSpec{refSpec},
+		Auth:       v.auth,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("pushing %q to %s: %v", branch, v.remote, err)
+	}
+
+	return nil
+}
+
+// commitAndPush commits any pending content changes and pushes them,
+// following config.GitMode:
+//   - "direct": commit and push the current branch, as handlePublish always
+//     did before go-git replaced the shelled-out git commands.
+//   - "branch-per-post": commit on a new edit/{slug} branch, push it, and
+//     open a pull request through the configured PRProvider.
+//
+// branchHint, when set, names the branch to use in branch-per-post mode
+// instead of a timestamp-derived one.
+func commitAndPush(branchHint string, sendEvent func(event, data string)) error {
+	v, err := newGoGitVCS(config.HugoSiteDir)
+	if err != nil {
+		return err
+	}
+
+	hasChanges, err := v.HasChanges()
+	if err != nil {
+		return err
+	}
+	if !hasChanges {
+		sendEvent("progress", "No git changes detected, skipping commit")
+		return nil
+	}
+
+	if config.GitMode != "branch-per-post" {
+		sendEvent("progress", "Git changes detected, creating commit...")
+		commitMsg := fmt.Sprintf("Auto-publish: %s", time.Now().Format("2006-01-02 15:04:05"))
+		if err := v.CommitAll(commitMsg); err != nil {
+			return err
+		}
+
+		branch, err := v.CurrentBranch()
+		if err != nil {
+			return err
+		}
+		sendEvent("progress", fmt.Sprintf("Pushing %s...", branch))
+		return v.Push(branch)
+	}
+
+	// branch-per-post: isolate the commit on edit/{slug} instead of the base
+	// branch, so the base branch only moves when the resulting PR merges.
+	base, err := v.CurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	branch := "edit/" + branchSlug(branchHint)
+	sendEvent("progress", fmt.Sprintf("Creating branch %s...", branch))
+	if err := v.CreateBranch(branch); err != nil {
+		return err
+	}
+	if err := v.Checkout(branch); err != nil {
+		return err
+	}
+	defer func() {
+		if err := v.Checkout(base); err != nil {
+			log.Printf("Error returning worktree to %s after publish: %v", base, err)
+		}
+	}()
+
+	sendEvent("progress", "Git changes detected, creating commit...")
+	commitMsg := fmt.Sprintf("Auto-publish: %s", time.Now().Format("2006-01-02 15:04:05"))
+	if err := v.CommitAll(commitMsg); err != nil {
+		return err
+	}
+
+	sendEvent("progress", fmt.Sprintf("Pushing %s...", branch))
+	if err := v.Push(branch); err != nil {
+		return err
+	}
+
+	provider := prProviderForConfig()
+	if provider == nil {
+		return nil
+	}
+
+	sendEvent("progress", "Opening pull request...")
+	prBase := config.GitBaseBranch
+	if prBase == "" {
+		prBase = "main"
+	}
+
+	url, err := provider.CreatePullRequest(branch, prBase, "Auto-publish: "+branch, "")
+	if err != nil {
+		sendEvent("progress", fmt.Sprintf("Warning: failed to open pull request: %v", err))
+		return nil
+	}
+	if url != "" {
+		sendEvent("progress", "Opened pull request: "+url)
+	}
+
+	return nil
+}
+
+// branchSlug picks the branch-per-post branch name: hint when the client
+// supplied one, otherwise a timestamp so concurrent publishes don't collide.
+func branchSlug(hint string) string {
+	if hint != "" {
+		return hint
+	}
+	return "publish-" + time.Now().Format("20060102-150405")
+}
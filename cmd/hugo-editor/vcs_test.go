@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// newTestGoGitVCS builds a goGitVCS over an in-memory billy filesystem and
+// storer, with one commit already on the default branch, so tests never
+// touch disk and don't need a real git remote.
+func newTestGoGitVCS(t *testing.T) *goGitVCS {
+	t.Helper()
+
+	fs := memfs.New()
+	repo, err := git.Init(memory.NewStorage(), fs)
+	if err != nil {
+		t.Fatalf("git.Init: %v", err)
+	}
+
+	sig := object.Signature{Name: "Test Author", Email: "test@example.com", When: time.Now()}
+
+	f, err := fs.Create("hello.txt")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := f.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	f.Close()
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Worktree: %v", err)
+	}
+	if _, err := wt.Add("hello.txt"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if _, err := wt.Commit("initial commit", &git.CommitOptions{Author: &sig}); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	return &goGitVCS{repo: repo, author: sig, remote: "origin"}
+}
+
+func TestGoGitVCSHasChangesAndCommitAll(t *testing.T) {
+	v := newTestGoGitVCS(t)
+
+	if changes, err := v.HasChanges(); err != nil || changes {
+		t.Fatalf("HasChanges() = %v, %v; want false, nil right after the initial commit", changes, err)
+	}
+
+	wt, err := v.repo.Worktree()
+	if err != nil {
+		t.Fatalf("Worktree: %v", err)
+	}
+	f, err := wt.Filesystem.Create("hello.txt")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := f.Write([]byte("hello, updated")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	f.Close()
+
+	if changes, err := v.HasChanges(); err != nil || !changes {
+		t.Fatalf("HasChanges() = %v, %v; want true, nil after modifying a tracked file", changes, err)
+	}
+
+	if err := v.CommitAll("update hello"); err != nil {
+		t.Fatalf("CommitAll: %v", err)
+	}
+
+	if changes, err := v.HasChanges(); err != nil || changes {
+		t.Fatalf("HasChanges() = %v, %v; want false, nil after CommitAll", changes, err)
+	}
+}
+
+func TestGoGitVCSCreateBranchAndCheckout(t *testing.T) {
+	v := newTestGoGitVCS(t)
+
+	base, err := v.CurrentBranch()
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+
+	if err := v.CreateBranch("edit/test-post"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := v.Checkout("edit/test-post"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	branch, err := v.CurrentBranch()
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+	if branch != "edit/test-post" {
+		t.Errorf("CurrentBranch() = %q, want edit/test-post", branch)
+	}
+
+	if err := v.Checkout(base); err != nil {
+		t.Fatalf("Checkout(%q): %v", base, err)
+	}
+	if branch, err := v.CurrentBranch(); err != nil || branch != base {
+		t.Errorf("CurrentBranch() = %q, %v; want %q after checking back out", branch, err, base)
+	}
+}
@@ -0,0 +1,55 @@
+// Package activitystreams builds the minimal ActivityStreams 2.0
+// representation of a post that GoBlog and other ActivityPub-speaking blogs
+// publish alongside their HTML, so federated readers can follow along.
+package activitystreams
+
+import "time"
+
+// context is the standard ActivityStreams JSON-LD context.
+const context = "https://www.w3.org/ns/activitystreams"
+
+// Public is the well-known "everyone" audience used in To.
+const Public = "https://www.w3.org/ns/activitystreams#Public"
+
+// Object is a post rendered as an ActivityStreams Article or Note.
+type Object struct {
+	Context      string   `json:"@context"`
+	Type         string   `json:"type"`
+	ID           string   `json:"id"`
+	URL          string   `json:"url"`
+	Name         string   `json:"name,omitempty"`
+	AttributedTo string   `json:"attributedTo"`
+	Content      string   `json:"content"`
+	Published    string   `json:"published"`
+	To           []string `json:"to"`
+}
+
+// New builds the ActivityStreams representation of a post. It's an Article
+// with Name set to title when title is non-empty, otherwise a Note (Name
+// left empty), matching how GoBlog distinguishes titled posts from short
+// notes and carries the title through to federated readers.
+func New(title, id, url, attributedTo, contentHTML string, published time.Time) Object {
+	objType := "Note"
+	if title != "" {
+		objType = "Article"
+	}
+
+	return Object{
+		Context:      context,
+		Type:         objType,
+		ID:           id,
+		URL:          url,
+		Name:         title,
+		AttributedTo: attributedTo,
+		Content:      contentHTML,
+		Published:    published.Format(time.RFC3339),
+		To:           []string{Public},
+	}
+}
+
+// TagURI builds a tag: URI (RFC 4151) identifying a post by the domain it's
+// published under and the date it first went out, e.g.
+// "tag:example.com,2024-01-15:/blog/slug".
+func TagURI(domain string, firstPublished time.Time, path string) string {
+	return "tag:" + domain + "," + firstPublished.Format("2006-01-02") + ":" + path
+}
@@ -0,0 +1,75 @@
+package activitystreams
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNewArticleVsNote(t *testing.T) {
+	published := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	article := New("Hello World", "tag:example.com,2024-01-15:/blog/hello-world/", "https://example.com/blog/hello-world/", "https://example.com/about/", "<p>Hello.</p>", published)
+	if article.Type != "Article" {
+		t.Errorf("Type = %q, want Article for a titled post", article.Type)
+	}
+
+	note := New("", "tag:example.com,2024-01-15:/notes/1/", "https://example.com/notes/1/", "https://example.com/about/", "<p>A note.</p>", published)
+	if note.Type != "Note" {
+		t.Errorf("Type = %q, want Note for an untitled post", note.Type)
+	}
+	if note.Name != "" {
+		t.Errorf("Name = %q, want empty for an untitled post", note.Name)
+	}
+	if article.Name != "Hello World" {
+		t.Errorf("Name = %q, want Hello World for a titled post", article.Name)
+	}
+}
+
+func TestNewJSON(t *testing.T) {
+	published := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	obj := New("Hello World", "tag:example.com,2024-01-15:/blog/hello-world/", "https://example.com/blog/hello-world/", "https://example.com/about/", "<p>Hello.</p>", published)
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	want := map[string]any{
+		"@context":     context,
+		"type":         "Article",
+		"id":           "tag:example.com,2024-01-15:/blog/hello-world/",
+		"url":          "https://example.com/blog/hello-world/",
+		"name":         "Hello World",
+		"attributedTo": "https://example.com/about/",
+		"content":      "<p>Hello.</p>",
+		"published":    "2024-01-15T10:00:00Z",
+		"to":           []any{Public},
+	}
+	for k, v := range want {
+		if gotV := got[k]; !jsonEqual(gotV, v) {
+			t.Errorf("field %q = %v, want %v", k, gotV, v)
+		}
+	}
+}
+
+func jsonEqual(a, b any) bool {
+	aEnc, _ := json.Marshal(a)
+	bEnc, _ := json.Marshal(b)
+	return string(aEnc) == string(bEnc)
+}
+
+func TestTagURI(t *testing.T) {
+	published := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	got := TagURI("example.com", published, "/blog/hello-world/")
+	want := "tag:example.com,2024-01-15:/blog/hello-world/"
+	if got != want {
+		t.Errorf("TagURI() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+// Package atom builds and serializes a minimal Atom 1.0 feed
+// (https://datatracker.ietf.org/doc/html/rfc4287) for a site's posts.
+package atom
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Feed is an Atom feed document.
+type Feed struct {
+	Title   string
+	ID      string
+	Link    string
+	Updated time.Time
+	Author  Person
+	Entries []Entry
+}
+
+// Person identifies a feed or entry author.
+type Person struct {
+	Name  string
+	Email string
+}
+
+// Entry is a single Atom entry, one per post.
+type Entry struct {
+	Title     string
+	ID        string
+	Link      string
+	Published time.Time
+	Updated   time.Time
+	Content   string
+}
+
+// xmlFeed mirrors the Atom schema; Feed is the friendlier type callers build,
+// this is what actually gets marshaled.
+type xmlFeed struct {
+	XMLName xml.Name   `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string     `xml:"title"`
+	ID      string     `xml:"id"`
+	Link    xmlLink    `xml:"link"`
+	Updated string     `xml:"updated"`
+	Author  xmlPerson  `xml:"author"`
+	Entries []xmlEntry `xml:"entry"`
+}
+
+type xmlLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type xmlPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+}
+
+type xmlEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Link      xmlLink    `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Content   xmlContent `xml:"content"`
+}
+
+type xmlContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// Write serializes f as an Atom 1.0 document to w.
+func (f Feed) Write(w io.Writer) error {
+	doc := xmlFeed{
+		Title:   f.Title,
+		ID:      f.ID,
+		Link:    xmlLink{Href: f.Link},
+		Updated: f.Updated.Format(time.RFC3339),
+		Author:  xmlPerson{Name: f.Author.Name, Email: f.Author.Email},
+	}
+
+	for _, e := range f.Entries {
+		doc.Entries = append(doc.Entries, xmlEntry{
+			Title:     e.Title,
+			ID:        e.ID,
+			Link:      xmlLink{Href: e.Link},
+			Published: e.Published.Format(time.RFC3339),
+			Updated:   e.Updated.Format(time.RFC3339),
+			Content:   xmlContent{Type: "html", Text: e.Content},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
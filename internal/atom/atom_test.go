@@ -0,0 +1,58 @@
+package atom
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeedWrite(t *testing.T) {
+	published := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	f := Feed{
+		Title:   "Example Feed",
+		ID:      "https://example.com/",
+		Link:    "https://example.com/",
+		Updated: published,
+		Author:  Person{Name: "Jane Doe", Email: "jane@example.com"},
+		Entries: []Entry{
+			{
+				Title:     "Hello World",
+				ID:        "tag:example.com,2024-01-15:/blog/hello-world/",
+				Link:      "https://example.com/blog/hello-world/",
+				Published: published,
+				Updated:   published,
+				Content:   "<p>Hello.</p>",
+			},
+		},
+	}
+
+	var buf strings.Builder
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got := buf.String()
+
+	wantSubstrings := []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		`<title>Example Feed</title>`,
+		`<id>https://example.com/</id>`,
+		`<link href="https://example.com/"></link>`,
+		`<updated>2024-01-15T10:00:00Z</updated>`,
+		`<name>Jane Doe</name>`,
+		`<email>jane@example.com</email>`,
+		`<entry>`,
+		`<id>tag:example.com,2024-01-15:/blog/hello-world/</id>`,
+		`<published>2024-01-15T10:00:00Z</published>`,
+		`<content type="html">&lt;p&gt;Hello.&lt;/p&gt;</content>`,
+	}
+	for _, want := range wantSubstrings {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write() output missing %q; got:\n%s", want, got)
+		}
+	}
+
+	if !strings.HasPrefix(got, `<?xml version="1.0" encoding="UTF-8"?>`) {
+		t.Errorf("Write() output missing XML header; got:\n%s", got)
+	}
+}